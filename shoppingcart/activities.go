@@ -0,0 +1,61 @@
+package shoppingcart
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pborman/uuid"
+	"go.temporal.io/sdk/activity"
+)
+
+// SendAbandonedCartEmailActivity notifies userEmail that their cart still has items in it.
+// It is idempotent and safe to retry: sending the same reminder twice for the same cart is
+// harmless to the recipient, since CartWorkflow only calls it once per abandonment cycle.
+func SendAbandonedCartEmailActivity(ctx context.Context, userEmail string, cart CartState) error {
+	activity.GetLogger(ctx).Info("Sending abandoned cart email", "UserEmail", userEmail, "Items", cart.Items)
+	return nil
+}
+
+// ReserveInventoryActivity reserves stock for every item in cart ahead of payment. It is safe
+// to retry: reserving the same cart twice is a no-op in a real inventory system keyed by a
+// reservation ID derived from the workflow/activity identity.
+func ReserveInventoryActivity(ctx context.Context, cart CartState) error {
+	activity.GetLogger(ctx).Info("Reserving inventory", "Items", cart.Items)
+	return nil
+}
+
+// ReleaseInventoryActivity undoes ReserveInventoryActivity. It is the compensating action run
+// by CheckoutWorkflow's saga when a later checkout step fails.
+func ReleaseInventoryActivity(ctx context.Context, cart CartState) error {
+	activity.GetLogger(ctx).Info("Releasing inventory", "Items", cart.Items)
+	return nil
+}
+
+// ChargePaymentActivity charges the user for cart and returns a payment ID that
+// RefundPaymentActivity can use to reverse the charge.
+func ChargePaymentActivity(ctx context.Context, cart CartState) (string, error) {
+	total := 0
+	for _, quantity := range cart.Items {
+		total += quantity
+	}
+	if total == 0 {
+		return "", errors.New("cannot charge payment for an empty cart")
+	}
+	paymentID := "payment-" + uuid.New()
+	activity.GetLogger(ctx).Info("Charging payment", "PaymentID", paymentID, "Items", cart.Items)
+	return paymentID, nil
+}
+
+// RefundPaymentActivity undoes ChargePaymentActivity. It is the compensating action run by
+// CheckoutWorkflow's saga when a later checkout step fails.
+func RefundPaymentActivity(ctx context.Context, paymentID string) error {
+	activity.GetLogger(ctx).Info("Refunding payment", "PaymentID", paymentID)
+	return nil
+}
+
+// CreateOrderActivity records the confirmed order and returns its order ID.
+func CreateOrderActivity(ctx context.Context, cart CartState, paymentID string) (string, error) {
+	orderID := "order-" + uuid.New()
+	activity.GetLogger(ctx).Info("Creating order", "OrderID", orderID, "PaymentID", paymentID, "Items", cart.Items)
+	return orderID, nil
+}
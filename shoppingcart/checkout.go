@@ -0,0 +1,83 @@
+package shoppingcart
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// CheckoutStatus is the lifecycle state of a CheckoutWorkflow run, as reported by
+// GetCheckoutStatusQueryName.
+type CheckoutStatus string
+
+const (
+	CheckoutStatusPending   CheckoutStatus = "pending"
+	CheckoutStatusConfirmed CheckoutStatus = "confirmed"
+	CheckoutStatusFailed    CheckoutStatus = "failed"
+)
+
+// GetCheckoutStatusQueryName is registered by CartWorkflow when it starts, so the HTTP layer can
+// poll progress instead of blocking on the checkout child workflow. Before any checkout has
+// started it returns a zero CheckoutResult, whose Status is empty.
+const GetCheckoutStatusQueryName = "getCheckoutStatus"
+
+// CheckoutResult is returned by GetCheckoutStatusQueryName. OrderID is only populated once
+// Status is CheckoutStatusConfirmed.
+type CheckoutResult struct {
+	Status  CheckoutStatus
+	OrderID string
+}
+
+// CheckoutWorkflow reserves inventory, charges payment, and creates an order for cart. It is
+// started as a child workflow of CartWorkflow when the user checks out.
+//
+// The three steps form a saga: each successful activity pushes a compensating action onto a
+// stack, and if any later step fails, the compensations already pushed are run in reverse order
+// before the workflow returns an error. This keeps inventory and payment consistent without a
+// distributed transaction.
+func CheckoutWorkflow(ctx workflow.Context, cart CartState) (CheckoutResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 5,
+		},
+	})
+
+	var compensations []func(workflow.Context) error
+	compensate := func() {
+		// Compensations run on a disconnected context so they still execute even if ctx was
+		// cancelled, and in reverse order so the most recent step is undone first.
+		cCtx, _ := workflow.NewDisconnectedContext(ctx)
+		logger := workflow.GetLogger(ctx)
+		for i := len(compensations) - 1; i >= 0; i-- {
+			if err := compensations[i](cCtx); err != nil {
+				logger.Error("Checkout compensation failed", "Error", err)
+			}
+		}
+	}
+
+	if err := workflow.ExecuteActivity(ctx, ReserveInventoryActivity, cart).Get(ctx, nil); err != nil {
+		return CheckoutResult{Status: CheckoutStatusFailed}, err
+	}
+	compensations = append(compensations, func(ctx workflow.Context) error {
+		return workflow.ExecuteActivity(ctx, ReleaseInventoryActivity, cart).Get(ctx, nil)
+	})
+
+	var paymentID string
+	if err := workflow.ExecuteActivity(ctx, ChargePaymentActivity, cart).Get(ctx, &paymentID); err != nil {
+		compensate()
+		return CheckoutResult{Status: CheckoutStatusFailed}, err
+	}
+	compensations = append(compensations, func(ctx workflow.Context) error {
+		return workflow.ExecuteActivity(ctx, RefundPaymentActivity, paymentID).Get(ctx, nil)
+	})
+
+	var orderID string
+	if err := workflow.ExecuteActivity(ctx, CreateOrderActivity, cart, paymentID).Get(ctx, &orderID); err != nil {
+		compensate()
+		return CheckoutResult{Status: CheckoutStatusFailed}, err
+	}
+
+	return CheckoutResult{Status: CheckoutStatusConfirmed, OrderID: orderID}, nil
+}
@@ -0,0 +1,56 @@
+package shoppingcart
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type CheckoutTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func TestCheckoutTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckoutTestSuite))
+}
+
+func (s *CheckoutTestSuite) TestCheckoutWorkflow_Success() {
+	env := s.NewTestWorkflowEnvironment()
+	cart := CartState{Items: map[string]int{"apple": 2}}
+
+	env.OnActivity(ReserveInventoryActivity, mock.Anything, cart).Return(nil).Once()
+	env.OnActivity(ChargePaymentActivity, mock.Anything, cart).Return("payment-1", nil).Once()
+	env.OnActivity(CreateOrderActivity, mock.Anything, cart, "payment-1").Return("order-1", nil).Once()
+
+	env.ExecuteWorkflow(CheckoutWorkflow, cart)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var result CheckoutResult
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal(CheckoutStatusConfirmed, result.Status)
+	s.Equal("order-1", result.OrderID)
+	env.AssertExpectations(s.T())
+}
+
+func (s *CheckoutTestSuite) TestCheckoutWorkflow_PaymentFailsReleasesInventory() {
+	env := s.NewTestWorkflowEnvironment()
+	cart := CartState{Items: map[string]int{"television": 1}}
+
+	env.OnActivity(ReserveInventoryActivity, mock.Anything, cart).Return(nil).Once()
+	// ChargePaymentActivity keeps failing across retries, so only assert it was attempted
+	// and that the compensation ran, not an exact call count.
+	env.OnActivity(ChargePaymentActivity, mock.Anything, cart).Return("", errors.New("card declined"))
+	env.OnActivity(ReleaseInventoryActivity, mock.Anything, cart).Return(nil).Once()
+
+	env.ExecuteWorkflow(CheckoutWorkflow, cart)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	env.AssertExpectations(s.T())
+}
@@ -0,0 +1,85 @@
+package shoppingcart
+
+import (
+	"context"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// CartActivities bundles activities that act on a cart workflow other than the one they run
+// inside of. The SDK has no workflow-to-workflow query/terminate call, so MergeCartWorkflow goes
+// through a Temporal client in an activity instead.
+type CartActivities struct {
+	Client client.Client
+}
+
+// QueryCartActivity reads the current state of the cart identified by cartID.
+func (a *CartActivities) QueryCartActivity(ctx context.Context, cartID string) (CartState, error) {
+	cartState := CartState{Items: make(map[string]int)}
+	value, err := a.Client.QueryWorkflow(ctx, cartID, "", QueryName)
+	if err != nil {
+		return cartState, err
+	}
+	if err := value.Get(&cartState); err != nil {
+		return cartState, err
+	}
+	return cartState, nil
+}
+
+// AddItemToCartActivity issues an "add" update against the cart identified by cartID, starting
+// it with update-with-start if it doesn't already exist.
+func (a *CartActivities) AddItemToCartActivity(ctx context.Context, cartID string, itemID string) error {
+	updateHandle, err := a.Client.UpdateWithStartWorkflow(ctx, client.UpdateWithStartWorkflowOptions{
+		StartWorkflowOperation: a.Client.NewWithStartWorkflowOperation(client.StartWorkflowOptions{
+			ID:                       cartID,
+			TaskQueue:                TaskQueueName,
+			WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+		}, CartWorkflow, CartWorkflowParams{}),
+		UpdateOptions: client.UpdateWorkflowOptions{
+			UpdateName:   UpdateName,
+			WaitForStage: client.WorkflowUpdateStageCompleted,
+			Args:         []interface{}{"add", itemID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return updateHandle.Get(ctx, nil)
+}
+
+// TerminateCartActivity terminates the cart workflow identified by cartID.
+func (a *CartActivities) TerminateCartActivity(ctx context.Context, cartID string, reason string) error {
+	return a.Client.TerminateWorkflow(ctx, cartID, "", reason)
+}
+
+// MergeCartWorkflow copies every item from anonymousCartID into userCartID, then terminates
+// anonymousCartID. It is started as a one-shot workflow when a request authenticates while
+// still carrying an anonymous session's cart ID, so the two carts don't silently diverge.
+func MergeCartWorkflow(ctx workflow.Context, anonymousCartID string, userCartID string) error {
+	var activities *CartActivities
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 5,
+		},
+	})
+
+	var anonymousCart CartState
+	if err := workflow.ExecuteActivity(ctx, activities.QueryCartActivity, anonymousCartID).Get(ctx, &anonymousCart); err != nil {
+		return err
+	}
+
+	for itemID, quantity := range anonymousCart.Items {
+		for i := 0; i < quantity; i++ {
+			if err := workflow.ExecuteActivity(ctx, activities.AddItemToCartActivity, userCartID, itemID).Get(ctx, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return workflow.ExecuteActivity(ctx, activities.TerminateCartActivity, anonymousCartID, "merged into authenticated user cart").Get(ctx, nil)
+}
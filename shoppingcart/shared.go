@@ -0,0 +1,14 @@
+package shoppingcart
+
+const (
+	// TaskQueueName is the task queue polled by the shopping cart worker.
+	TaskQueueName = "shoppingCartTaskQueue"
+
+	// UpdateName is registered by CartWorkflow to handle add/remove actions. Checkout is sent
+	// as the "checkout" signal instead.
+	UpdateName = "cartAction"
+
+	// QueryName is registered by CartWorkflow to return the current cart state without
+	// mutating it, so reads don't produce history events the way an update would.
+	QueryName = "getCart"
+)
@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/pborman/uuid"
-	"github.com/temporalio/samples-go/shoppingcart"
-	enumspb "go.temporal.io/api/enums/v1"
-	"go.temporal.io/sdk/client"
 	"log"
 	"net/http"
 	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/temporalio/samples-go/shoppingcart"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
 )
 
 var (
@@ -24,7 +29,6 @@ var (
 		"car":        5000000,
 		"binder":     1000,
 	}
-	sessionId = newSession()
 )
 
 func main() {
@@ -36,16 +40,49 @@ func main() {
 		panic(err)
 	}
 
-	http.HandleFunc("/", listHandler)
-	http.HandleFunc("/action", actionHandler)
+	router := mux.NewRouter()
+	router.HandleFunc("/", listHandler).Methods(http.MethodGet)
+	router.HandleFunc("/list", listHandler).Methods(http.MethodGet)
+	router.HandleFunc("/action", actionHandler)
+
+	apiRouter := router.PathPrefix("/api/v1").Subrouter()
+	apiRouter.HandleFunc("/carts/{id}", getCartHandler).Methods(http.MethodGet, http.MethodOptions)
+	apiRouter.HandleFunc("/carts/{id}/items", addItemHandler).Methods(http.MethodPost, http.MethodOptions)
+	apiRouter.HandleFunc("/carts/{id}/items/{itemID}", removeItemHandler).Methods(http.MethodDelete, http.MethodOptions)
+	apiRouter.HandleFunc("/carts/{id}/checkout", checkoutHandler).Methods(http.MethodPost, http.MethodOptions)
+	apiRouter.HandleFunc("/carts/{id}/checkout", checkoutStatusHandler).Methods(http.MethodGet, http.MethodOptions)
+	apiRouter.HandleFunc("/updates/{updateID}", updateStatusHandler).Methods(http.MethodGet, http.MethodOptions)
+	// Every route above must also accept OPTIONS: gorilla/mux doesn't route a method-mismatched
+	// request through a route's middleware chain, so without this a CORS preflight for any
+	// non-"simple" request (e.g. a JSON POST) would 404/405 before corsMiddleware ever runs.
+	apiRouter.Use(corsMiddleware)
 
 	fmt.Println("Shopping Cart UI available at http://localhost:8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	if err := http.ListenAndServe(":8080", router); err != nil {
 		fmt.Println("Error starting server:", err)
 	}
 }
 
-func listHandler(w http.ResponseWriter, _ *http.Request) {
+// corsMiddleware allows SPA and mobile clients on other origins to call the JSON API directly,
+// rather than going through the server-rendered HTML pages.
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func listHandler(w http.ResponseWriter, r *http.Request) {
+	renderCartPage(w, cartIDForRequest(w, r))
+}
+
+func renderCartPage(w http.ResponseWriter, cartID string) {
 	w.Header().Set("Content-Type", "text/html") // Set the content type to HTML
 	_, _ = fmt.Fprint(w, "<h1>SAMPLE SHOPPING WEBSITE</h1>"+
 		"<a href=\"/list\">HOME</a> <a href=\"/action?type=checkout\">Checkout</a>"+
@@ -65,7 +102,13 @@ func listHandler(w http.ResponseWriter, _ *http.Request) {
 	_, _ = fmt.Fprint(w, "</table><h3>Current items in cart:</h3>"+
 		"<table border=1><tr><th>Item</th><th>Quantity</th><th>Action</th>")
 
-	cartState := updateWithStartCart("list", "")
+	cartState, err := queryCart(cartID)
+	if err != nil && !isNotFound(err) {
+		// A cart that hasn't been started yet is not an error: update-with-start creates it on
+		// the first add, so every brand-new session legitimately queries an empty cart before
+		// that happens.
+		log.Fatalln("Error querying cart:", err)
+	}
 
 	// List current items in cart
 	keys = make([]string, 0)
@@ -82,47 +125,209 @@ func listHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 func actionHandler(w http.ResponseWriter, r *http.Request) {
+	cartID := cartIDForRequest(w, r)
+
 	actionType := r.URL.Query().Get("type")
 	switch actionType {
 	case "checkout":
-		err := workflowClient.SignalWorkflow(context.Background(), sessionId, "", "checkout", nil)
+		err := workflowClient.SignalWorkflow(context.Background(), cartID, "", "checkout", nil)
 		if err != nil {
 			log.Fatalln("Error signaling checkout:", err)
 		}
-	case "add", "remove", "list":
+	case "add", "remove":
 		itemID := r.URL.Query().Get("itemID")
-		updateWithStartCart(actionType, itemID)
+		if _, err := updateWithStartCart(cartID, actionType, itemID); err != nil {
+			log.Fatalln("Error updating cart:", err)
+		}
 	default:
 		log.Fatalln("Invalid action type:", actionType)
 	}
 
-	// Generate the HTML after communicating with the Temporal workflow.
-	// "list" already generates HTML, so skip for that scenario
-	if actionType != "list" {
-		listHandler(w, r)
+	renderCartPage(w, cartID)
+}
+
+// getCartHandler handles GET /api/v1/carts/{id}. It reads the cart via a query, so listing a
+// cart never produces a history event the way updateWithStartCart would.
+func getCartHandler(w http.ResponseWriter, r *http.Request) {
+	cartID := mux.Vars(r)["id"]
+	cartState, err := queryCart(cartID)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
+	writeJSON(w, http.StatusOK, cartState)
+}
+
+type addItemRequest struct {
+	ItemID string `json:"itemID"`
 }
 
-func updateWithStartCart(actionType string, itemID string) shoppingcart.CartState {
-	// Handle a client request to add an item to the shopping cart. The user is not logged in, but a session ID is
-	// available from a cookie, and we use this as the cart ID. The Temporal client was created at service-start
-	// time and is shared by all request handlers.
-	//
-	// A Workflow Type exists that can be used to represent a shopping cart. The method uses update-with-start to
-	// add an item to the shopping cart, creating the cart if it doesn't already exist.
-	//
-	// Note that the workflow handle is available, even if the Update fails.
+// addItemHandler handles POST /api/v1/carts/{id}/items. By default it returns as soon as the
+// update is accepted; pass ?wait=completed to block for the full result instead.
+func addItemHandler(w http.ResponseWriter, r *http.Request) {
+	cartID := mux.Vars(r)["id"]
+
+	var req addItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ItemID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "itemID is required"})
+		return
+	}
+
+	respondToUpdate(w, r, cartID, "add", req.ItemID)
+}
+
+// removeItemHandler handles DELETE /api/v1/carts/{id}/items/{itemID}. By default it returns as
+// soon as the update is accepted; pass ?wait=completed to block for the full result instead.
+func removeItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	respondToUpdate(w, r, vars["id"], "remove", vars["itemID"])
+}
+
+// respondToUpdate issues a cart update and writes the HTTP response for it, choosing between
+// the synchronous and async-with-polling code paths based on the "wait" query parameter.
+func respondToUpdate(w http.ResponseWriter, r *http.Request, cartID string, actionType string, itemID string) {
+	if r.URL.Query().Get("wait") == "completed" {
+		cartState, err := updateWithStartCart(cartID, actionType, itemID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, cartState)
+		return
+	}
+
+	updateID, err := updateWithStartCartAsync(cartID, actionType, itemID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"cartID": cartID, "updateID": updateID})
+}
+
+// checkoutHandler handles POST /api/v1/carts/{id}/checkout.
+func checkoutHandler(w http.ResponseWriter, r *http.Request) {
+	cartID := mux.Vars(r)["id"]
+	err := workflowClient.SignalWorkflow(r.Context(), cartID, "", "checkout", nil)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "checkout started"})
+}
+
+// checkoutStatusHandler handles GET /api/v1/carts/{id}/checkout, returning the cart's most
+// recent checkout progress (pending/confirmed/failed) and order ID, if any.
+func checkoutStatusHandler(w http.ResponseWriter, r *http.Request) {
+	cartID := mux.Vars(r)["id"]
+	value, err := workflowClient.QueryWorkflow(r.Context(), cartID, "", shoppingcart.GetCheckoutStatusQueryName)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var result shoppingcart.CheckoutResult
+	if err := value.Get(&result); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// updateStatusHandler handles GET /api/v1/updates/{updateID}?cartID=<id>. It resolves an
+// update that was issued with WaitForStage=Accepted, blocking briefly so async clients can
+// long-poll for the result rather than re-requesting in a tight loop.
+func updateStatusHandler(w http.ResponseWriter, r *http.Request) {
+	updateID := mux.Vars(r)["updateID"]
+	cartID := r.URL.Query().Get("cartID")
+	if cartID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cartID query parameter is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 25*time.Second)
+	defer cancel()
+
+	updateHandle := workflowClient.GetWorkflowUpdateHandle(client.GetWorkflowUpdateHandleOptions{
+		WorkflowID: cartID,
+		UpdateID:   updateID,
+	})
+
+	cartState := shoppingcart.CartState{Items: make(map[string]int)}
+	if err := updateHandle.Get(ctx, &cartState); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			writeJSON(w, http.StatusAccepted, map[string]string{"status": "pending"})
+			return
+		}
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, cartState)
+}
+
+// writeError maps a Temporal client error to an HTTP status code and writes it as JSON.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case isUpdateRejected(err):
+		status = http.StatusBadRequest
+	case isNotFound(err):
+		status = http.StatusNotFound
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func isNotFound(err error) bool {
+	var notFound *serviceerror.NotFound
+	return errors.As(err, &notFound)
+}
+
+func isUpdateRejected(err error) bool {
+	var invalidArgument *serviceerror.InvalidArgument
+	if errors.As(err, &invalidArgument) {
+		return true
+	}
+	var failedPrecondition *serviceerror.FailedPrecondition
+	return errors.As(err, &failedPrecondition)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// queryCart reads the current cart state without mutating the workflow.
+func queryCart(cartID string) (shoppingcart.CartState, error) {
+	cartState := shoppingcart.CartState{Items: make(map[string]int)}
+	value, err := workflowClient.QueryWorkflow(context.Background(), cartID, "", shoppingcart.QueryName)
+	if err != nil {
+		return cartState, err
+	}
+	if err := value.Get(&cartState); err != nil {
+		return cartState, err
+	}
+	return cartState, nil
+}
+
+// updateWithStartCart handles a client request to mutate the shopping cart identified by
+// cartID. The Temporal client was created at service-start time and is shared by all request
+// handlers.
+//
+// A Workflow Type exists that can be used to represent a shopping cart. The method uses
+// update-with-start to mutate the shopping cart, creating the cart if it doesn't already exist.
+//
+// Note that the workflow handle is available, even if the Update fails.
+func updateWithStartCart(cartID string, actionType string, itemID string) (shoppingcart.CartState, error) {
 	ctx := context.Background()
 
 	updateWithStartOptions := client.UpdateWithStartWorkflowOptions{
 		StartWorkflowOperation: workflowClient.NewWithStartWorkflowOperation(client.StartWorkflowOptions{
-			ID:        sessionId,
+			ID:        cartID,
 			TaskQueue: shoppingcart.TaskQueueName,
 			// WorkflowIDConflictPolicy is required when using UpdateWithStartWorkflow.
 			// Here we use USE_EXISTING, because we want to reuse the running workflow, as it
 			// is long-running and keeping track of our cart state.
 			WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
-		}, shoppingcart.CartWorkflow, nil),
+		}, shoppingcart.CartWorkflow, shoppingcart.CartWorkflowParams{}),
 		UpdateOptions: client.UpdateWorkflowOptions{
 			UpdateName:   shoppingcart.UpdateName,
 			WaitForStage: client.WorkflowUpdateStageCompleted,
@@ -135,7 +340,7 @@ func updateWithStartCart(actionType string, itemID string) shoppingcart.CartStat
 		// policy or invalid workflow argument types in the start operation), or
 		// a server-side failure (e.g. failed to start workflow, or exceeded
 		// limit on concurrent update per workflow execution).
-		log.Fatalln("Error issuing update-with-start:", err)
+		return shoppingcart.CartState{}, err
 	}
 
 	log.Println("Updated workflow",
@@ -145,11 +350,33 @@ func updateWithStartCart(actionType string, itemID string) shoppingcart.CartStat
 	// Always use a zero variable before calling Get for any Go SDK API
 	cartState := shoppingcart.CartState{Items: make(map[string]int)}
 	if err = updateHandle.Get(ctx, &cartState); err != nil {
-		log.Fatalln("Error obtaining update result:", err)
+		return shoppingcart.CartState{}, err
 	}
-	return cartState
+	return cartState, nil
 }
 
-func newSession() string {
-	return "session-" + uuid.New()
+// updateWithStartCartAsync mirrors updateWithStartCart, but only waits for the update to be
+// accepted rather than completed. This keeps the HTTP request from blocking on the update
+// handler, which may be slow if, for example, an activity it calls stalls. The caller polls
+// GET /api/v1/updates/{updateID}?cartID=<cartID> for the eventual result.
+func updateWithStartCartAsync(cartID string, actionType string, itemID string) (string, error) {
+	ctx := context.Background()
+
+	updateWithStartOptions := client.UpdateWithStartWorkflowOptions{
+		StartWorkflowOperation: workflowClient.NewWithStartWorkflowOperation(client.StartWorkflowOptions{
+			ID:                       cartID,
+			TaskQueue:                shoppingcart.TaskQueueName,
+			WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+		}, shoppingcart.CartWorkflow, shoppingcart.CartWorkflowParams{}),
+		UpdateOptions: client.UpdateWorkflowOptions{
+			UpdateName:   shoppingcart.UpdateName,
+			WaitForStage: client.WorkflowUpdateStageAccepted,
+			Args:         []interface{}{actionType, itemID},
+		},
+	}
+	updateHandle, err := workflowClient.UpdateWithStartWorkflow(ctx, updateWithStartOptions)
+	if err != nil {
+		return "", err
+	}
+	return updateHandle.UpdateID(), nil
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.temporal.io/sdk/client"
+)
+
+// slowUpdateHandle stands in for an update whose handler hasn't completed yet. Its Get method
+// is never expected to be called by the async path under test; if it were, the call would
+// panic on the nil embedded handle and fail the test instead of passing for the wrong reason.
+type slowUpdateHandle struct {
+	client.WorkflowUpdateHandle
+}
+
+func (slowUpdateHandle) WorkflowID() string { return "test-cart" }
+func (slowUpdateHandle) RunID() string      { return "test-run" }
+func (slowUpdateHandle) UpdateID() string   { return "test-update" }
+
+// acceptedOnlyClient fakes just enough of client.Client to exercise the async update-with-start
+// path without a real Temporal service.
+type acceptedOnlyClient struct {
+	client.Client
+}
+
+func (acceptedOnlyClient) NewWithStartWorkflowOperation(options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) client.WithStartWorkflowOperation {
+	return nil
+}
+
+func (acceptedOnlyClient) UpdateWithStartWorkflow(ctx context.Context, options client.UpdateWithStartWorkflowOptions) (client.WorkflowUpdateHandle, error) {
+	return slowUpdateHandle{}, nil
+}
+
+func TestAddItemHandler_ReturnsOnAcceptWithoutWaitingForCompletion(t *testing.T) {
+	original := workflowClient
+	workflowClient = acceptedOnlyClient{}
+	defer func() { workflowClient = original }()
+
+	body, _ := json.Marshal(addItemRequest{ItemID: "apple"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/carts/test-cart/items", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "test-cart"})
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	addItemHandler(rec, req)
+	elapsed := time.Since(start)
+
+	if elapsed > 100*time.Millisecond {
+		t.Fatalf("expected addItemHandler to return almost immediately, took %s", elapsed)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["updateID"] != "test-update" {
+		t.Fatalf("expected updateID %q, got %q", "test-update", resp["updateID"])
+	}
+}
+
+func TestAddItemHandler_SyncModeWaitsForCompletion(t *testing.T) {
+	original := workflowClient
+	workflowClient = acceptedOnlyClient{}
+	defer func() { workflowClient = original }()
+
+	body, _ := json.Marshal(addItemRequest{ItemID: "apple"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/carts/test-cart/items?wait=completed", bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "test-cart"})
+	rec := httptest.NewRecorder()
+
+	// slowUpdateHandle.Get is inherited from the nil embedded client.WorkflowUpdateHandle, so
+	// calling it panics - which is exactly what proves the sync path actually waits on Get
+	// instead of returning after accept like the default path does.
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected the synchronous path to call Get on the update handle")
+		}
+	}()
+	addItemHandler(rec, req)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/pborman/uuid"
+	"github.com/temporalio/samples-go/shoppingcart"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+const sessionCookieName = "cart_session"
+
+// Authenticator maps an Authorization header value to a user ID. It exists so the demo
+// staticAuthenticator below can be swapped for a real identity provider without touching the
+// handlers that depend on it.
+type Authenticator interface {
+	Authenticate(authHeader string) (userID string, ok bool)
+}
+
+// staticAuthenticator is a placeholder Authenticator backed by a fixed bearer-token-to-user
+// map, standing in for a real identity provider.
+type staticAuthenticator map[string]string
+
+func (a staticAuthenticator) Authenticate(authHeader string) (string, bool) {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(authHeader, prefix) {
+		return "", false
+	}
+	userID, ok := a[strings.TrimPrefix(authHeader, prefix)]
+	return userID, ok
+}
+
+var authenticator Authenticator = staticAuthenticator{
+	"demo-token": "alice",
+}
+
+// cartIDForRequest returns the workflow ID to use as the cart for r. A bearer token that
+// authenticator recognizes always wins and maps to "cart-<userID>"; otherwise the anonymous
+// session cookie is used, creating one if r doesn't have one yet.
+//
+// If an authenticated request arrives bearing an anonymous session cookie, that anonymous
+// cart is merged into the user's cart and the cookie is cleared so the merge only happens once.
+func cartIDForRequest(w http.ResponseWriter, r *http.Request) string {
+	anonymousCartID, hasSession := sessionCookieValue(r)
+
+	if userID, ok := authenticator.Authenticate(r.Header.Get("Authorization")); ok {
+		userCartID := "cart-" + userID
+		if hasSession {
+			startCartMerge(anonymousCartID, userCartID)
+			clearSessionCookie(w, r)
+		}
+		return userCartID
+	}
+
+	if !hasSession {
+		anonymousCartID = newSession()
+		setSessionCookie(w, r, anonymousCartID)
+	}
+	return anonymousCartID
+}
+
+func sessionCookieValue(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+func setSessionCookie(w http.ResponseWriter, r *http.Request, sessionID string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    sessionID,
+		Path:     "/",
+		Secure:   isTLS(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   isTLS(r),
+		HttpOnly: true,
+	})
+}
+
+// isTLS reports whether r arrived over TLS, directly or (per a conventional reverse-proxy
+// header) terminated in front of this server. A Secure cookie set on a plain-HTTP response is
+// silently dropped by most browsers, so this sample must only ask for one when it's true.
+func isTLS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// startCartMerge kicks off a one-shot MergeCartWorkflow that copies anonymousCartID's items
+// into userCartID and terminates anonymousCartID. The workflow ID is derived from the anonymous
+// cart so a retried or duplicate request can't start the merge twice.
+func startCartMerge(anonymousCartID, userCartID string) {
+	_, err := workflowClient.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
+		ID:        "merge-" + anonymousCartID,
+		TaskQueue: shoppingcart.TaskQueueName,
+		// Without this the default policy would allow a new merge run once the first one closed.
+		WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+	}, shoppingcart.MergeCartWorkflow, anonymousCartID, userCartID)
+	if err != nil {
+		log.Println("Error starting cart merge:", err)
+	}
+}
+
+func newSession() string {
+	return "session-" + uuid.New()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+
+	"github.com/temporalio/samples-go/shoppingcart"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+func main() {
+	c, err := client.Dial(client.Options{
+		HostPort: client.DefaultHostPort,
+	})
+	if err != nil {
+		log.Fatalln("Unable to create client", err)
+	}
+	defer c.Close()
+
+	w := worker.New(c, shoppingcart.TaskQueueName, worker.Options{})
+	w.RegisterWorkflow(shoppingcart.CartWorkflow)
+	w.RegisterWorkflow(shoppingcart.CheckoutWorkflow)
+	w.RegisterWorkflow(shoppingcart.MergeCartWorkflow)
+	w.RegisterActivity(shoppingcart.SendAbandonedCartEmailActivity)
+	w.RegisterActivity(shoppingcart.ReserveInventoryActivity)
+	w.RegisterActivity(shoppingcart.ReleaseInventoryActivity)
+	w.RegisterActivity(shoppingcart.ChargePaymentActivity)
+	w.RegisterActivity(shoppingcart.RefundPaymentActivity)
+	w.RegisterActivity(shoppingcart.CreateOrderActivity)
+	w.RegisterActivity(&shoppingcart.CartActivities{Client: c})
+
+	if err := w.Run(worker.InterruptCh()); err != nil {
+		log.Fatalln("Unable to start worker", err)
+	}
+}
@@ -0,0 +1,179 @@
+package shoppingcart
+
+import (
+	"errors"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// defaultReminderDelay is how long a cart can sit idle before an abandoned-cart email is sent,
+// if CartWorkflowParams.ReminderDelay is not set.
+const defaultReminderDelay = 10 * time.Minute
+
+// CartState is the current contents of a shopping cart, keyed by item ID.
+type CartState struct {
+	Items map[string]int
+}
+
+// CartWorkflowParams configures the abandoned-cart reminder for a CartWorkflow run. Both
+// fields are optional: a zero ReminderDelay falls back to defaultReminderDelay, and a zero
+// UserEmail is passed to SendAbandonedCartEmailActivity as is; the reminder still runs.
+type CartWorkflowParams struct {
+	ReminderDelay time.Duration
+	UserEmail     string
+}
+
+// CartWorkflow represents a single user's shopping cart for the lifetime of their session.
+// It is kept running (as a "long-lived" workflow) so that add/remove/checkout actions can be
+// delivered as updates and signals against the same workflow ID.
+func CartWorkflow(ctx workflow.Context, params CartWorkflowParams) error {
+	state := CartState{Items: make(map[string]int)}
+	checkedOut := false
+	reminderSent := false
+
+	reminderDelay := params.ReminderDelay
+	if reminderDelay <= 0 {
+		reminderDelay = defaultReminderDelay
+	}
+
+	logger := workflow.GetLogger(ctx)
+
+	// The reminder timer is cancelled and re-armed every time an add/remove update is
+	// received, so it only fires once the cart has sat untouched for reminderDelay.
+	var cancelReminderTimer workflow.CancelFunc
+	var reminderTimer workflow.Future
+	armReminderTimer := func() {
+		if cancelReminderTimer != nil {
+			cancelReminderTimer()
+		}
+		timerCtx, cancel := workflow.WithCancel(ctx)
+		cancelReminderTimer = cancel
+		reminderTimer = workflow.NewTimer(timerCtx, reminderDelay)
+	}
+	// resetReminderCycle starts a brand new abandonment cycle: it re-arms the countdown AND
+	// clears reminderSent, so a genuine add/remove lets the email fire again later. Re-arming
+	// after the timer merely fires (see the reminderTimer callback below) must NOT clear
+	// reminderSent, or an idle-but-untouched cart would get the email resent every cycle.
+	resetReminderCycle := func() {
+		armReminderTimer()
+		reminderSent = false
+	}
+	resetReminderCycle()
+
+	err := workflow.SetUpdateHandlerWithOptions(
+		ctx,
+		UpdateName,
+		func(ctx workflow.Context, actionType string, itemID string) (CartState, error) {
+			switch actionType {
+			case "add":
+				state.Items[itemID]++
+				resetReminderCycle()
+			case "remove":
+				if state.Items[itemID] > 0 {
+					state.Items[itemID]--
+					if state.Items[itemID] == 0 {
+						delete(state.Items, itemID)
+					}
+				}
+				resetReminderCycle()
+			default:
+				return CartState{}, errors.New("unknown cart action type: " + actionType)
+			}
+			return state, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(ctx workflow.Context, actionType string, itemID string) error {
+				switch actionType {
+				case "add", "remove":
+					return nil
+				default:
+					return errors.New("unknown cart action type: " + actionType)
+				}
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := workflow.SetQueryHandler(ctx, QueryName, func() (CartState, error) {
+		return state, nil
+	}); err != nil {
+		return err
+	}
+
+	var checkoutResult CheckoutResult
+	checkoutStarted := false
+	var checkoutFuture workflow.Future
+	if err := workflow.SetQueryHandler(ctx, GetCheckoutStatusQueryName, func() (CheckoutResult, error) {
+		return checkoutResult, nil
+	}); err != nil {
+		return err
+	}
+
+	checkoutChan := workflow.GetSignalChannel(ctx, "checkout")
+
+	for !checkedOut {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(checkoutChan, func(c workflow.ReceiveChannel, more bool) {
+			c.Receive(ctx, nil)
+			if checkoutStarted {
+				return
+			}
+			checkoutStarted = true
+			checkoutResult = CheckoutResult{Status: CheckoutStatusPending}
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID: "checkout-" + workflow.GetInfo(ctx).WorkflowExecution.ID,
+			})
+			checkoutFuture = workflow.ExecuteChildWorkflow(childCtx, CheckoutWorkflow, state)
+		})
+		selector.AddFuture(reminderTimer, func(f workflow.Future) {
+			if err := f.Get(ctx, nil); err != nil {
+				// The timer was cancelled by armReminderTimer because the user added or
+				// removed an item; a fresh timer is already running.
+				return
+			}
+			// The timer ran to completion, so it's now a resolved future that would make
+			// every future Selector.Select return immediately; re-arm it before this
+			// callback returns so the workflow keeps blocking between events.
+			defer armReminderTimer()
+			if reminderSent || len(state.Items) == 0 {
+				return
+			}
+			// Mark the cycle as reminded before sending, so an add/remove that arrives while
+			// the activity runs resets the cycle instead of being overwritten afterwards.
+			reminderSent = true
+			activityCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+				StartToCloseTimeout: time.Minute,
+				RetryPolicy: &temporal.RetryPolicy{
+					MaximumAttempts: 5,
+				},
+			})
+			if err := workflow.ExecuteActivity(activityCtx, SendAbandonedCartEmailActivity, params.UserEmail, state).Get(activityCtx, nil); err != nil {
+				logger.Error("Failed to send abandoned cart email", "Error", err)
+			}
+		})
+		if checkoutFuture != nil {
+			selector.AddFuture(checkoutFuture, func(f workflow.Future) {
+				var res CheckoutResult
+				if err := f.Get(ctx, &res); err != nil {
+					// A failed checkout (e.g. payment declined) shouldn't end the cart's
+					// lifecycle: leave the cart's items in place and let the user retry or
+					// keep shopping instead of closing this workflow execution.
+					logger.Error("Checkout failed", "Error", err)
+					checkoutResult = CheckoutResult{Status: CheckoutStatusFailed}
+					checkoutStarted = false
+					checkoutFuture = nil
+					return
+				}
+				checkoutResult = res
+				checkedOut = true
+			})
+		}
+		selector.Select(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package shoppingcart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+)
+
+type UnitTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func TestUnitTestSuite(t *testing.T) {
+	suite.Run(t, new(UnitTestSuite))
+}
+
+// noopUpdateCallback discards the accept/reject/complete notifications for updates delivered
+// via env.UpdateWorkflow in tests that only care about the resulting workflow state.
+type noopUpdateCallback struct{}
+
+func (noopUpdateCallback) Accept()                     {}
+func (noopUpdateCallback) Reject(error)                {}
+func (noopUpdateCallback) Complete(interface{}, error) {}
+
+// mockSuccessfulCheckout registers the CheckoutWorkflow child and stubs its activities so a
+// "checkout" signal completes CartWorkflow.
+func mockSuccessfulCheckout(env *testsuite.TestWorkflowEnvironment) {
+	env.RegisterWorkflow(CheckoutWorkflow)
+	env.OnActivity(ReserveInventoryActivity, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(ChargePaymentActivity, mock.Anything, mock.Anything).Return("payment-1", nil)
+	env.OnActivity(CreateOrderActivity, mock.Anything, mock.Anything, mock.Anything).Return("order-1", nil)
+}
+
+func (s *UnitTestSuite) TestAbandonedCartReminder_SentAfterInactivity() {
+	env := s.NewTestWorkflowEnvironment()
+	mockSuccessfulCheckout(env)
+	env.OnActivity(SendAbandonedCartEmailActivity, mock.Anything, "shopper@example.com", mock.Anything).Return(nil).Once()
+
+	// The reminder is only sent for a non-empty cart, so add an item first; the reminder then
+	// fires once at 11m, before checkout at 15m.
+	env.RegisterDelayedCallback(func() {
+		env.UpdateWorkflow(UpdateName, "", noopUpdateCallback{}, "add", "apple")
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("checkout", nil)
+	}, 15*time.Minute)
+
+	env.ExecuteWorkflow(CartWorkflow, CartWorkflowParams{
+		ReminderDelay: 10 * time.Minute,
+		UserEmail:     "shopper@example.com",
+	})
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	env.AssertExpectations(s.T())
+}
+
+func (s *UnitTestSuite) TestAbandonedCartReminder_ResetByActivity() {
+	env := s.NewTestWorkflowEnvironment()
+	mockSuccessfulCheckout(env)
+	env.OnActivity(SendAbandonedCartEmailActivity, mock.Anything, "", mock.Anything).Return(nil).Maybe()
+
+	env.RegisterDelayedCallback(func() {
+		env.UpdateWorkflow(UpdateName, "", noopUpdateCallback{}, "add", "apple")
+	}, 6*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow("checkout", nil)
+	}, 13*time.Minute)
+
+	env.ExecuteWorkflow(CartWorkflow, CartWorkflowParams{ReminderDelay: 10 * time.Minute})
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	// The add at 6m resets the timer, so the reminder (which would otherwise fire at 10m)
+	// never has ten uninterrupted minutes before checkout at 13m.
+	env.AssertNotCalled(s.T(), "SendAbandonedCartEmailActivity", mock.Anything, "", mock.Anything)
+}